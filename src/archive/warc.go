@@ -0,0 +1,108 @@
+// Package archive writes fetched pages to a WARC (Web ARChive) file so a
+// crawl can be mirrored for later offline inspection alongside the
+// broken-link report.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// warcDateFormat is the timestamp format required by the WARC 1.1 spec for
+// the WARC-Date header (ISO 8601, UTC, second precision).
+const warcDateFormat = "2006-01-02T15:04:05Z"
+
+// Writer appends WARC 1.1 records to a file, gzip-compressing each record
+// as its own gzip member so the resulting .warc.gz can be read or truncated
+// record-by-record like any other WARC file.
+type Writer struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewWriter creates (truncating if necessary) the WARC file at path and
+// writes the leading warcinfo record describing this crawl.
+func NewWriter(path, userAgent string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create WARC file: %w", err)
+	}
+
+	w := &Writer{file: f}
+
+	info := fmt.Sprintf("software: broken_link_detector\r\nformat: WARC File Format 1.1\r\nhttp-header-user-agent: %s\r\n", userAgent)
+	if err := w.writeRecord("warcinfo", "", "application/warc-fields", []byte(info)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// WriteResponse appends a WARC "response" record containing the raw HTTP
+// response bytes (status line, headers and body) captured while fetching
+// targetURI.
+func (w *Writer) WriteResponse(targetURI string, raw []byte) error {
+	return w.writeRecord("response", targetURI, "application/http; msgtype=response", raw)
+}
+
+// WriteRequest appends a WARC "request" record containing the raw HTTP
+// request bytes sent for targetURI.
+func (w *Writer) WriteRequest(targetURI string, raw []byte) error {
+	return w.writeRecord("request", targetURI, "application/http; msgtype=request", raw)
+}
+
+// Close flushes and closes the underlying WARC file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+func (w *Writer) writeRecord(warcType, targetURI, contentType string, content []byte) error {
+	var header bytes.Buffer
+	header.WriteString("WARC/1.1\r\n")
+	fmt.Fprintf(&header, "WARC-Type: %s\r\n", warcType)
+	if targetURI != "" {
+		fmt.Fprintf(&header, "WARC-Target-URI: %s\r\n", targetURI)
+	}
+	fmt.Fprintf(&header, "WARC-Date: %s\r\n", time.Now().UTC().Format(warcDateFormat))
+	fmt.Fprintf(&header, "WARC-Record-ID: <urn:uuid:%s>\r\n", newRecordID())
+	fmt.Fprintf(&header, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&header, "Content-Length: %d\r\n", len(content))
+	header.WriteString("\r\n")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	gz := gzip.NewWriter(w.file)
+	if _, err := gz.Write(header.Bytes()); err != nil {
+		gz.Close()
+		return fmt.Errorf("failed to write WARC record header: %w", err)
+	}
+	if _, err := gz.Write(content); err != nil {
+		gz.Close()
+		return fmt.Errorf("failed to write WARC record content: %w", err)
+	}
+	// Each WARC record ends with a blank line separating it from the next.
+	if _, err := gz.Write([]byte("\r\n\r\n")); err != nil {
+		gz.Close()
+		return fmt.Errorf("failed to write WARC record trailer: %w", err)
+	}
+	return gz.Close()
+}
+
+// newRecordID returns a random UUID-shaped string for WARC-Record-ID. It
+// isn't a full RFC 4122 implementation, just enough entropy to keep record
+// IDs unique within a crawl.
+func newRecordID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,84 @@
+package archive
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewWriterWritesWarcinfoWithUserAgent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.warc.gz")
+
+	w, err := NewWriter(path, "mybot/2.0")
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	raw := readAllGzipMembers(t, path)
+	if !strings.Contains(raw, "WARC-Type: warcinfo") {
+		t.Errorf("expected a warcinfo record, got:\n%s", raw)
+	}
+	if !strings.Contains(raw, "http-header-user-agent: mybot/2.0") {
+		t.Errorf("expected the warcinfo record to carry the configured user agent, got:\n%s", raw)
+	}
+}
+
+func TestWriteResponseAndRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.warc.gz")
+
+	w, err := NewWriter(path, "mybot/2.0")
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if err := w.WriteRequest("https://example.com/", []byte("GET / HTTP/1.1\r\n\r\n")); err != nil {
+		t.Fatalf("WriteRequest: %v", err)
+	}
+	if err := w.WriteResponse("https://example.com/", []byte("HTTP/1.1 200 OK\r\n\r\nhello")); err != nil {
+		t.Fatalf("WriteResponse: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	raw := readAllGzipMembers(t, path)
+	for _, want := range []string{
+		"WARC-Type: request",
+		"WARC-Type: response",
+		"WARC-Target-URI: https://example.com/",
+		"hello",
+	} {
+		if !strings.Contains(raw, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, raw)
+		}
+	}
+}
+
+// readAllGzipMembers decompresses every concatenated gzip member in the
+// WARC file (each record is its own member) into one string of WARC text.
+func readAllGzipMembers(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	gz.Multistream(true)
+	defer gz.Close()
+
+	out, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("reading decompressed WARC content: %v", err)
+	}
+	return string(out)
+}
@@ -0,0 +1,112 @@
+// Package client performs the crawler's outbound HTTP requests, applying
+// a per-host rate limit and retrying transient failures with exponential
+// backoff.
+package client
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Client executes HTTP requests on behalf of the crawler, limiting each
+// host to a configured requests-per-second rate and retrying connection
+// errors and 5xx responses with exponential backoff plus jitter.
+type Client struct {
+	httpClient *http.Client
+	rps        float64
+	maxRetries int
+	minDelay   time.Duration
+	userAgent  string
+
+	mu       sync.Mutex
+	limiters map[string]*rateLimiter
+}
+
+// New returns a Client that limits each host to rps requests per second
+// (0 means unlimited) and retries a failed request up to maxRetries times
+// beyond the initial attempt. minDelay, if nonzero, sets a floor on the
+// delay between requests to any one host even when rps alone would allow
+// more; it's raised further by EnsureMinInterval for hosts whose
+// robots.txt requests a longer Crawl-delay. userAgent is sent on every
+// outbound request.
+func New(rps float64, maxRetries int, userAgent string, minDelay time.Duration) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		rps:        rps,
+		maxRetries: maxRetries,
+		minDelay:   minDelay,
+		userAgent:  userAgent,
+		limiters:   make(map[string]*rateLimiter),
+	}
+}
+
+// Do executes req, waiting for this client's per-host rate limit before
+// every attempt. It retries on connection errors and 5xx responses,
+// backing off exponentially with jitter between attempts. If every
+// attempt fails with a connection error, the returned error is a
+// *ConnError so callers can report it distinctly from a permanent HTTP
+// status.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	limiter := c.limiterFor(req.URL.Host)
+
+	var resp *http.Response
+	var err error
+	for attempt := 0; ; attempt++ {
+		limiter.wait()
+
+		attemptReq := req.Clone(req.Context())
+		attemptReq.Header.Set("User-Agent", c.userAgent)
+		resp, err = c.httpClient.Do(attemptReq)
+		if err == nil && resp.StatusCode < http.StatusInternalServerError {
+			return resp, nil
+		}
+		if attempt >= c.maxRetries {
+			break
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+		time.Sleep(backoff(attempt))
+	}
+
+	if err != nil {
+		return nil, &ConnError{URL: req.URL.String(), Err: err}
+	}
+	return resp, nil
+}
+
+// EnsureMinInterval raises host's per-host delay to at least d; a longer
+// delay already enforced by rps or minDelay is kept. It's used to honor a
+// robots.txt Crawl-delay directive discovered after the client was
+// constructed.
+func (c *Client) EnsureMinInterval(host string, d time.Duration) {
+	c.limiterFor(host).raise(d)
+}
+
+// backoff returns an exponentially growing delay (100ms, 200ms, 400ms,
+// ...) for the given zero-based attempt, with up to an equal amount of
+// random jitter added so retrying clients don't all retry in lockstep.
+func backoff(attempt int) time.Duration {
+	base := time.Duration(math.Pow(2, float64(attempt))) * 100 * time.Millisecond
+	return base + time.Duration(rand.Int63n(int64(base)+1))
+}
+
+// ConnError indicates a transport-level failure (DNS, timeout, connection
+// refused) as opposed to a non-retryable HTTP status, so callers can tell
+// a transient network blip apart from a genuinely broken link.
+type ConnError struct {
+	URL string
+	Err error
+}
+
+// Error implements error.
+func (e *ConnError) Error() string {
+	return fmt.Sprintf("connection error fetching %s: %v", e.URL, e.Err)
+}
+
+// Unwrap supports errors.Is/errors.As against the underlying cause.
+func (e *ConnError) Unwrap() error { return e.Err }
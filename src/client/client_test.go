@@ -0,0 +1,107 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiterInterval(t *testing.T) {
+	cases := []struct {
+		name     string
+		rps      float64
+		minDelay time.Duration
+		want     time.Duration
+	}{
+		{"rps only", 4, 0, 250 * time.Millisecond},
+		{"unlimited rps", 0, 0, 0},
+		{"minDelay stricter than rps", 2, time.Second, time.Second},
+		{"rps stricter than minDelay", 10, time.Millisecond, 100 * time.Millisecond},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rl := newRateLimiter(c.rps, c.minDelay)
+			if rl.interval != c.want {
+				t.Errorf("interval = %v, want %v", rl.interval, c.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterRaiseOnlyIncreases(t *testing.T) {
+	rl := newRateLimiter(0, 10*time.Millisecond)
+	rl.raise(5 * time.Millisecond)
+	if rl.interval != 10*time.Millisecond {
+		t.Errorf("raise to a smaller value changed interval to %v, want unchanged 10ms", rl.interval)
+	}
+	rl.raise(50 * time.Millisecond)
+	if rl.interval != 50*time.Millisecond {
+		t.Errorf("interval = %v, want 50ms after raising to a larger value", rl.interval)
+	}
+}
+
+func TestEnsureMinIntervalRaisesPerHostLimiter(t *testing.T) {
+	c := New(0, 0, "test-agent/1.0", 0)
+	c.EnsureMinInterval("example.com", 42*time.Millisecond)
+	if got := c.limiterFor("example.com").interval; got != 42*time.Millisecond {
+		t.Errorf("interval = %v, want 42ms", got)
+	}
+}
+
+func TestDoSendsUserAgentAndRetriesOn5xx(t *testing.T) {
+	var attempts int32
+	var gotUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := New(0, 3, "mybot/9.0", 0)
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200 after retrying past the 5xx responses", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+	if gotUserAgent != "mybot/9.0" {
+		t.Errorf("User-Agent = %q, want mybot/9.0", gotUserAgent)
+	}
+}
+
+func TestDoReturnsConnErrorAfterExhaustingRetries(t *testing.T) {
+	c := New(0, 1, "test-agent/1.0", 0)
+	// Nothing is listening on this address, so every attempt fails at
+	// the transport level rather than with an HTTP status.
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:1", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	_, err = c.Do(req)
+	if err == nil {
+		t.Fatal("Do: expected an error, got nil")
+	}
+	var connErr *ConnError
+	if !errors.As(err, &connErr) {
+		t.Errorf("Do error = %v, want a *ConnError", err)
+	}
+}
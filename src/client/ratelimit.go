@@ -0,0 +1,69 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter paces requests to a single host to roughly rps requests per
+// second by making each call to wait block until the minimum interval
+// since the previous call has elapsed.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+// newRateLimiter returns a rateLimiter for the given requests-per-second
+// rate and minimum per-host delay, whichever is stricter. A rate of 0 or
+// less disables rps-based pacing, leaving only minDelay in effect.
+func newRateLimiter(rps float64, minDelay time.Duration) *rateLimiter {
+	interval := minDelay
+	if rps > 0 {
+		if rpsInterval := time.Duration(float64(time.Second) / rps); rpsInterval > interval {
+			interval = rpsInterval
+		}
+	}
+	return &rateLimiter{interval: interval}
+}
+
+// raise increases the limiter's interval to at least d, if it isn't
+// already stricter.
+func (r *rateLimiter) raise(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d > r.interval {
+		r.interval = d
+	}
+}
+
+// wait blocks, if necessary, until this host's next request slot.
+func (r *rateLimiter) wait() {
+	if r.interval == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	next := r.last.Add(r.interval)
+	if next.After(now) {
+		time.Sleep(next.Sub(now))
+		now = next
+	}
+	r.last = now
+}
+
+// limiterFor returns (creating if necessary) the rate limiter for host.
+func (c *Client) limiterFor(host string) *rateLimiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rl, ok := c.limiters[host]
+	if !ok {
+		rl = newRateLimiter(c.rps, c.minDelay)
+		c.limiters[host] = rl
+	}
+	return rl
+}
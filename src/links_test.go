@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestExtractLinksClassification(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`
+		<html><body>
+			<a href="/about">About</a>
+			<link href="/style.css" rel="stylesheet">
+			<img src="/logo.png">
+			<script src="/app.js"></script>
+			<div style="background: url('/bg.png')"></div>
+		</body></html>
+	`))
+	if err != nil {
+		t.Fatalf("html.Parse: %v", err)
+	}
+
+	links := extractLinks(doc)
+
+	want := map[string]LinkClass{
+		"/about":     TagPrimary,
+		"/style.css": TagRelated,
+		"/logo.png":  TagRelated,
+		"/app.js":    TagRelated,
+		"/bg.png":    TagRelated,
+	}
+	got := make(map[string]LinkClass, len(links))
+	for _, l := range links {
+		got[l.URL] = l.Class
+	}
+
+	for url, class := range want {
+		c, ok := got[url]
+		if !ok {
+			t.Errorf("expected to find a link to %s", url)
+			continue
+		}
+		if c != class {
+			t.Errorf("link %s classified as %v, want %v", url, c, class)
+		}
+	}
+}
+
+func TestExtractCSSLinks(t *testing.T) {
+	links := extractCSSLinks(`background: url("/a.png"); border-image: url(/b.png)`)
+	if len(links) != 2 {
+		t.Fatalf("got %d links, want 2: %+v", len(links), links)
+	}
+	for _, l := range links {
+		if l.Class != TagRelated {
+			t.Errorf("CSS url() link %s should be TagRelated, got %v", l.URL, l.Class)
+		}
+	}
+}
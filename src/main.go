@@ -1,20 +1,53 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"net/http/httputil"
 	"net/url"
+	"regexp"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/jshiles/broken_link_detector/archive"
+	"github.com/jshiles/broken_link_detector/client"
+	"github.com/jshiles/broken_link_detector/queue"
+	"github.com/jshiles/broken_link_detector/report"
+	"github.com/jshiles/broken_link_detector/robots"
 	"golang.org/x/net/html"
 )
 
-// Link represents a hyperlink with its URL and HTTP status.
+// queueCapacity bounds the work-available signal channel so a burst of
+// discovered links can't grow unboundedly while all workers are busy.
+const queueCapacity = 4096
+
+// LinkClass classifies how a Link was discovered.
+type LinkClass int
+
+const (
+	// TagPrimary marks links discovered via <a href>. Primary links are
+	// the ones a crawl recurses into.
+	TagPrimary LinkClass = iota
+	// TagRelated marks links discovered via <link href>, <img src>,
+	// <script src>, or a CSS url(...) reference. Related links are
+	// checked for broken status but are never recursed into.
+	TagRelated
+)
+
+// Link represents a hyperlink with its URL, HTTP status, and the class of
+// tag it was discovered through. ConnErr is set when the final status
+// check attempt failed with a connection error rather than yielding an
+// HTTP status.
 type Link struct {
-	URL    string
-	Status int
+	URL     string
+	Status  int
+	Class   LinkClass
+	ConnErr bool
 }
 
 // WebPage represents a webpage with its URL and a list of extracted links.
@@ -28,6 +61,18 @@ func main() {
 	rootURL := flag.String("url", "", "The starting URL for crawling")
 	maxDepth := flag.Int("depth", 1, "The maximum depth for crawling")
 	verbose := flag.Bool("verbose", false, "Controls the logging to the screen")
+	output := flag.String("output", "", "Path to a WARC file (e.g. crawl.warc.gz) to mirror every fetched page and related resource (images, stylesheets, scripts) into")
+	scopeName := flag.String("scope", "depth", "Crawl scope policy: depth (default), host, or prefix")
+	concurrency := flag.Int("concurrency", 20, "Number of worker goroutines used to crawl pages and check links")
+	stateFile := flag.String("state", "", "Path to a BoltDB file used to persist crawl progress, enabling -resume")
+	resume := flag.Bool("resume", false, "Resume a previous crawl from the -state file instead of starting fresh")
+	rps := flag.Float64("rps", 2, "Maximum requests per second to a single host (0 = unlimited)")
+	maxRetries := flag.Int("max-retries", 3, "Maximum retry attempts for connection errors and 5xx responses")
+	reportPath := flag.String("report", "", "Path to write a structured report of broken links")
+	reportFormat := flag.String("format", "json", "Report format when -report is set: json or sarif")
+	userAgent := flag.String("user-agent", "broken_link_detector/1.0", "User-Agent header sent on every outbound request, and the name matched against robots.txt")
+	respectRobots := flag.Bool("respect-robots", true, "Consult each host's robots.txt and skip URLs it disallows for -user-agent")
+	crawlDelay := flag.Duration("crawl-delay", 0, "Minimum delay between requests to a single host (e.g. 500ms); the longest of this, the -rps interval and any robots.txt Crawl-delay applies")
 	flag.Parse()
 
 	// Validate flags
@@ -37,71 +82,329 @@ func main() {
 	if *maxDepth < 0 {
 		log.Fatal("The -depth flag must be a non-negative integer.")
 	}
+	if *concurrency < 1 {
+		log.Fatal("The -concurrency flag must be a positive integer.")
+	}
+	if *resume && *stateFile == "" {
+		log.Fatal("The -resume flag requires -state to also be set.")
+	}
+	if *maxRetries < 0 {
+		log.Fatal("The -max-retries flag must be a non-negative integer.")
+	}
+	if *reportPath != "" && *reportFormat != "json" && *reportFormat != "sarif" {
+		log.Fatalf("The -format flag must be json or sarif, got %q.", *reportFormat)
+	}
 
-	visited := make(map[string]bool)
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	var warcWriter *archive.Writer
+	if *output != "" {
+		var err error
+		warcWriter, err = archive.NewWriter(*output, *userAgent)
+		if err != nil {
+			log.Fatalf("Failed to open WARC output %s: %v", *output, err)
+		}
+		defer warcWriter.Close()
+	}
 
-	log.Printf("Starting crawl at: %s, Depth: %d\n", *rootURL, *maxDepth)
-	crawl(*rootURL, *maxDepth, 0, *verbose, &visited, &mu, &wg)
+	seed, err := url.Parse(*rootURL)
+	if err != nil {
+		log.Fatalf("The -url flag is not a valid URL: %v", err)
+	}
+	scope := newScope(*scopeName)
+
+	var q queue.Queue
+	if *stateFile != "" {
+		q, err = queue.NewBolt(*stateFile, *resume)
+		if err != nil {
+			log.Fatalf("Failed to open crawl state at %s: %v", *stateFile, err)
+		}
+	} else {
+		q = queue.NewMemory()
+	}
+	defer q.Close()
 
-	wg.Wait() // Wait for all goroutines to finish
+	httpClient := client.New(*rps, *maxRetries, *userAgent, *crawlDelay)
+
+	var robotsChecker *robots.Checker
+	if *respectRobots {
+		robotsChecker = robots.New(httpClient, *userAgent)
+	}
+
+	log.Printf("Starting crawl at: %s, Depth: %d, Concurrency: %d\n", *rootURL, *maxDepth, *concurrency)
+	runCrawl(*rootURL, *maxDepth, *concurrency, *verbose, warcWriter, scope, seed, q, *resume, httpClient, robotsChecker, *reportPath, *reportFormat)
 }
 
-// crawl recursively fetches pages up to a specified depth and processes their links.
-func crawl(pageURL string, maxDepth, currentDepth int, verbose bool, visited *map[string]bool, mu *sync.Mutex, wg *sync.WaitGroup) {
-	if currentDepth > maxDepth {
-		return
+// runCrawl drives a fixed-size worker pool over the frontier held by q.
+// Termination is tracked with an in-flight counter rather than a
+// sync.WaitGroup seeded from within recursion (incrementing a WaitGroup
+// from inside the goroutines it's waiting on races): the counter is
+// incremented before a URL is pushed and decremented once a worker
+// finishes processing it, and the signal channel is closed once it
+// reaches zero.
+func runCrawl(rootURL string, maxDepth, concurrency int, verbose bool, warcWriter *archive.Writer, scope Scope, seed *url.URL, q queue.Queue, resume bool, httpClient *client.Client, robotsChecker *robots.Checker, reportPath, reportFormat string) {
+	collector := report.NewCollector()
+	avail := make(chan struct{}, queueCapacity)
+	var inFlight int64
+	var closeOnce sync.Once
+
+	markOneDone := func() {
+		if atomic.AddInt64(&inFlight, -1) == 0 {
+			closeOnce.Do(func() { close(avail) })
+		}
+	}
+
+	enqueue := func(url string, depth int, referrer string) {
+		added, err := q.Push(url, depth, referrer)
+		if err != nil {
+			log.Printf("Error persisting crawl state for %s: %v", url, err)
+			return
+		}
+		if added {
+			atomic.AddInt64(&inFlight, 1)
+			avail <- struct{}{}
+		}
+	}
+
+	// Workers must be running before avail is pre-filled below: avail is
+	// bounded at queueCapacity, and a resumed crawl can have more pending
+	// URLs than that, which would otherwise block the fill with nobody
+	// yet draining the channel.
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for range avail {
+				item, ok, err := q.Pop()
+				if err != nil {
+					log.Printf("Error reading crawl state: %v", err)
+					markOneDone()
+					continue
+				}
+				if !ok {
+					// Another worker already claimed this item.
+					markOneDone()
+					continue
+				}
+				processURL(item, maxDepth, concurrency, verbose, warcWriter, scope, seed, q, enqueue, httpClient, robotsChecker, collector)
+				markOneDone()
+			}
+		}()
 	}
 
-	mu.Lock()
-	if (*visited)[pageURL] {
-		mu.Unlock()
+	if resume {
+		pending, err := q.Pending()
+		if err != nil {
+			log.Fatalf("Failed to load resumable crawl state: %v", err)
+		}
+		log.Printf("Resuming crawl with %d pending URL(s)", len(pending))
+		if len(pending) > 0 {
+			// Reserve the full count in inFlight before sending anything:
+			// incrementing one item at a time here, interleaved with
+			// workers already draining avail, could transiently bring
+			// inFlight to zero mid-loop and close avail while this loop
+			// still had items left to send, panicking on the next send.
+			atomic.AddInt64(&inFlight, int64(len(pending)))
+			for range pending {
+				avail <- struct{}{}
+			}
+		} else {
+			// Nothing left to do: no decrement will ever bring inFlight
+			// to zero, so close avail here to let the workers exit.
+			closeOnce.Do(func() { close(avail) })
+		}
+	} else {
+		enqueue(rootURL, 0, "")
+	}
+
+	workers.Wait()
+
+	broken, err := q.Broken()
+	if err != nil {
+		log.Printf("Error reading broken-link state: %v", err)
+		return
+	}
+	connErrors, err := q.ConnErrors()
+	if err != nil {
+		log.Printf("Error reading connection-error state: %v", err)
 		return
 	}
-	(*visited)[pageURL] = true
-	mu.Unlock()
+	log.Printf("Crawl complete. %d broken link(s) and %d connection error(s) recorded.", len(broken), len(connErrors))
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
+	if reportPath != "" {
+		if err := report.Write(reportPath, reportFormat, collector.Results()); err != nil {
+			log.Printf("Error writing report to %s: %v", reportPath, err)
+		}
+	}
+}
 
-		webPage, err := fetchWebPage(pageURL)
+// processURL fetches a single page, checks its links for broken status,
+// and enqueues any in-scope primary links for further crawling. Page and
+// broken-link outcomes are persisted to q as they're discovered.
+func processURL(item queue.Item, maxDepth, concurrency int, verbose bool, warcWriter *archive.Writer, scope Scope, seed *url.URL, q queue.Queue, enqueue func(string, int, string), httpClient *client.Client, robotsChecker *robots.Checker, collector *report.Collector) {
+	// maxDepth is enforced here, independent of which Scope is selected:
+	// SeedHostScope and SeedPrefixScope judge links purely by host/prefix
+	// and never look at depth, so without this guard -depth would be a
+	// no-op under those scopes.
+	if item.Depth > maxDepth {
+		if err := q.MarkDone(item.URL, 0, false); err != nil {
+			log.Printf("Error persisting crawl state for %s: %v", item.URL, err)
+		}
+		return
+	}
+
+	if robotsChecker != nil {
+		allowed, err := robotsChecker.Allowed(item.URL)
 		if err != nil {
-			log.Printf("Error fetching page %s: %v", pageURL, err)
+			log.Printf("Error checking robots.txt for %s: %v", item.URL, err)
+		}
+		if !allowed {
+			log.Printf("Skipping %s: disallowed by robots.txt", item.URL)
+			if err := q.MarkDone(item.URL, 0, false); err != nil {
+				log.Printf("Error persisting crawl state for %s: %v", item.URL, err)
+			}
 			return
 		}
+	}
 
-		log.Printf("Fetched: %s, Depth: %d", webPage.URL, currentDepth)
-		brokenLinks := fetchURLsConcurrently(webPage.Links, verbose)
-		log.Printf("Broken Links on %s:", webPage.URL)
-		for _, link := range brokenLinks {
-			log.Printf("- %s (Status: %d)", link.URL, link.Status)
+	webPage, err := fetchWebPage(item.URL, warcWriter, httpClient)
+	if err != nil {
+		log.Printf("Error fetching page %s: %v", item.URL, err)
+		var connErr *client.ConnError
+		if err := q.MarkDone(item.URL, 0, errors.As(err, &connErr)); err != nil {
+			log.Printf("Error persisting crawl state for %s: %v", item.URL, err)
 		}
+		return
+	}
+	if err := q.MarkDone(item.URL, http.StatusOK, false); err != nil {
+		log.Printf("Error persisting crawl state for %s: %v", item.URL, err)
+	}
 
-		// Recursively process child links
-		for _, link := range webPage.Links {
-			absoluteURL, err := resolveURL(pageURL, link.URL)
-			if err == nil {
-				crawl(absoluteURL, maxDepth, currentDepth+1, verbose, visited, mu, wg)
+	// Resolve every link to an absolute URL up front and drop any that
+	// the scope rules out of checking entirely.
+	var checkable []Link
+	for _, link := range webPage.Links {
+		absoluteURL, err := resolveURL(item.URL, link.URL)
+		if err != nil {
+			continue
+		}
+		link.URL = absoluteURL
+		if !scope.Allowed(seed, link.URL, link.Class, item.Depth, maxDepth) {
+			continue
+		}
+		if robotsChecker != nil {
+			allowed, err := robotsChecker.Allowed(link.URL)
+			if err != nil {
+				log.Printf("Error checking robots.txt for %s: %v", link.URL, err)
+			}
+			if !allowed {
+				if verbose {
+					log.Printf("Skipping %s: disallowed by robots.txt", link.URL)
+				}
+				continue
 			}
 		}
-	}()
+		checkable = append(checkable, link)
+	}
+
+	log.Printf("Fetched: %s, Depth: %d", webPage.URL, item.Depth)
+	brokenLinks := fetchURLsConcurrently(checkable, concurrency, verbose, warcWriter, httpClient)
+	log.Printf("Broken Links on %s:", webPage.URL)
+	now := time.Now()
+	chain := referrerChain(q, webPage.URL)
+	reportedLinks := make([]report.BrokenLink, 0, len(brokenLinks))
+	for _, link := range brokenLinks {
+		if link.ConnErr {
+			log.Printf("- %s (connection error)", link.URL)
+		} else {
+			log.Printf("- %s (Status: %d)", link.URL, link.Status)
+		}
+		if err := q.MarkDone(link.URL, link.Status, link.ConnErr); err != nil {
+			log.Printf("Error persisting broken-link state for %s: %v", link.URL, err)
+		}
+		reportedLinks = append(reportedLinks, report.BrokenLink{
+			URL:       link.URL,
+			Status:    link.Status,
+			ConnError: link.ConnErr,
+			Referrer:  chain,
+			Timestamp: now,
+		})
+	}
+	collector.Add(webPage.URL, reportedLinks)
+
+	// Enqueue primary links only, subject to the scope's depth decision
+	// for the depth they'd be fetched at.
+	for _, link := range checkable {
+		if link.Class != TagPrimary {
+			continue
+		}
+		if !scope.Allowed(seed, link.URL, link.Class, item.Depth+1, maxDepth) {
+			continue
+		}
+		enqueue(link.URL, item.Depth+1, item.URL)
+	}
+}
+
+// referrerChain walks q's persisted referrer pointers backward from
+// pageURL to the seed (whose referrer is empty) and returns the full path
+// from seed to pageURL, so a report can show how a broken link's page was
+// reached rather than just its immediate parent. seen guards against a
+// referrer cycle turning this into an infinite loop.
+func referrerChain(q queue.Queue, pageURL string) []string {
+	chain := []string{pageURL}
+	seen := map[string]bool{pageURL: true}
+
+	current := pageURL
+	for {
+		ref, ok, err := q.Referrer(current)
+		if err != nil || !ok || ref == "" || seen[ref] {
+			break
+		}
+		chain = append([]string{ref}, chain...)
+		seen[ref] = true
+		current = ref
+	}
+	return chain
 }
 
-// fetchWebPage fetches a webpage by URL and extracts its links.
-func fetchWebPage(pageURL string) (*WebPage, error) {
-	resp, err := http.Get(pageURL)
+// fetchWebPage fetches a webpage by URL and extracts its links, using
+// httpClient for rate-limited, retrying delivery. When warcWriter is
+// non-nil, the raw request/response bytes are also appended to the WARC
+// file so the page can be mirrored for archival purposes.
+func fetchWebPage(pageURL string, warcWriter *archive.Writer, httpClient *client.Client) (*WebPage, error) {
+	req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch the URL: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// Dumping the response reads and restores resp.Body, so the raw bytes
+	// can be archived without disturbing the html.Parse below.
+	rawResp, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture raw response: %w", err)
+	}
+
+	if warcWriter != nil {
+		if rawReq, err := httputil.DumpRequestOut(req, false); err == nil {
+			if err := warcWriter.WriteRequest(pageURL, rawReq); err != nil {
+				log.Printf("Error writing WARC request record for %s: %v", pageURL, err)
+			}
+		}
+		if err := warcWriter.WriteResponse(pageURL, rawResp); err != nil {
+			log.Printf("Error writing WARC response record for %s: %v", pageURL, err)
+		}
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("HTTP status %s", resp.Status)
 	}
 
-	doc, err := html.Parse(resp.Body)
+	doc, err := html.Parse(bytes.NewReader(rawBody(rawResp)))
 	if err != nil {
 		return nil, fmt.Errorf("error parsing HTML: %w", err)
 	}
@@ -110,18 +413,48 @@ func fetchWebPage(pageURL string) (*WebPage, error) {
 	return &WebPage{URL: pageURL, Links: links}, nil
 }
 
-// extractLinks traverses an HTML document and returns all hyperlinks as a list of Links.
+// rawBody strips the HTTP header section from a dumped request/response,
+// returning just the body bytes that follow the blank-line separator.
+func rawBody(raw []byte) []byte {
+	if i := bytes.Index(raw, []byte("\r\n\r\n")); i >= 0 {
+		return raw[i+4:]
+	}
+	return raw
+}
+
+// cssURLPattern matches CSS url(...) references, e.g. inside a <style>
+// block or a style="" attribute.
+var cssURLPattern = regexp.MustCompile(`url\(\s*['"]?([^'")]+)['"]?\s*\)`)
+
+// extractLinks traverses an HTML document and returns every discovered
+// link, classified as primary (<a href>) or related (<link href>,
+// <img src>, <script src>, and CSS url(...) references).
 func extractLinks(n *html.Node) []Link {
 	var links []Link
 	var f func(*html.Node)
 	f = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "a" {
-			for _, attr := range n.Attr {
-				if attr.Key == "href" {
-					links = append(links, Link{URL: attr.Val})
-					break
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "a":
+				if href, ok := nodeAttr(n, "href"); ok {
+					links = append(links, Link{URL: href, Class: TagPrimary})
+				}
+			case "link":
+				if href, ok := nodeAttr(n, "href"); ok {
+					links = append(links, Link{URL: href, Class: TagRelated})
+				}
+			case "img", "script":
+				if src, ok := nodeAttr(n, "src"); ok {
+					links = append(links, Link{URL: src, Class: TagRelated})
+				}
+			case "style":
+				if n.FirstChild != nil {
+					links = append(links, extractCSSLinks(n.FirstChild.Data)...)
 				}
 			}
+			if style, ok := nodeAttr(n, "style"); ok {
+				links = append(links, extractCSSLinks(style)...)
+			}
 		}
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
 			f(c)
@@ -131,6 +464,26 @@ func extractLinks(n *html.Node) []Link {
 	return links
 }
 
+// nodeAttr returns the value of the named attribute on n, if present.
+func nodeAttr(n *html.Node, key string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// extractCSSLinks scans css for url(...) references and returns each as a
+// related Link.
+func extractCSSLinks(css string) []Link {
+	var links []Link
+	for _, match := range cssURLPattern.FindAllStringSubmatch(css, -1) {
+		links = append(links, Link{URL: match[1], Class: TagRelated})
+	}
+	return links
+}
+
 // resolveURL converts a relative URL to an absolute one based on the base URL.
 func resolveURL(base, href string) (string, error) {
 	baseURL, err := url.Parse(base)
@@ -144,23 +497,29 @@ func resolveURL(base, href string) (string, error) {
 	return absoluteURL.String(), nil
 }
 
-// fetchURLsConcurrently fetches the URLs concurrently and returns those
-// that respond with HTTP status 400 or 500 errors.
-func fetchURLsConcurrently(links []Link, verbose bool) []Link {
+// fetchURLsConcurrently fetches the URLs concurrently, at most concurrency
+// at a time, and returns those that respond with an HTTP 4xx/5xx status or
+// fail with a connection error after retries are exhausted. When
+// warcWriter is non-nil, related resources (images, stylesheets, scripts)
+// are GETed in full and mirrored into it, rather than just HEAD-checked.
+func fetchURLsConcurrently(links []Link, concurrency int, verbose bool, warcWriter *archive.Writer, httpClient *client.Client) []Link {
 	var brokenLinks []Link
 	var wg sync.WaitGroup
 	ch := make(chan Link)
+	sem := make(chan struct{}, concurrency)
 
 	for _, link := range links {
 		wg.Add(1)
 		go func(link Link) {
 			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 			if verbose {
 				log.Printf("Fetching URL: %s", link.URL)
 			}
-			link.fetchStatus()
-			if link.Status >= 400 && link.Status < 600 {
-				log.Printf("Broken URL: %s, Status: %d", link.URL, link.Status)
+			link.fetchStatus(httpClient, warcWriter)
+			if link.ConnErr || (link.Status >= 400 && link.Status < 600) {
+				log.Printf("Broken URL: %s, Status: %d, ConnErr: %t", link.URL, link.Status, link.ConnErr)
 				ch <- link
 			} else {
 				log.Printf("Valid URL: %s, Status: %d", link.URL, link.Status)
@@ -182,14 +541,89 @@ func fetchURLsConcurrently(links []Link, verbose bool) []Link {
 	return brokenLinks
 }
 
-// fetchStatus fetches the HTTP status of the Link and updates its Status field.
-func (l *Link) fetchStatus() {
-	resp, err := http.Get(l.URL)
+// fetchStatus fetches the HTTP status of the Link and updates its Status
+// field, using httpClient for rate-limited, retrying delivery. It probes
+// with HEAD to avoid downloading the response body, and falls back to GET
+// when the server doesn't support HEAD (405). If every attempt fails with
+// a connection error rather than an HTTP status, ConnErr is set instead
+// of Status. When warcWriter is non-nil and l is a related resource (an
+// image, stylesheet, or script rather than a recursable <a href>), it is
+// instead GETed in full and mirrored into warcWriter, per fetchAndArchive.
+func (l *Link) fetchStatus(httpClient *client.Client, warcWriter *archive.Writer) {
+	if warcWriter != nil && l.Class == TagRelated {
+		l.fetchAndArchive(httpClient, warcWriter)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodHead, l.URL, nil)
 	if err != nil {
-		log.Printf("Error fetching URL: %s, Error: %v", l.URL, err)
-		l.Status = 0 // Use 0 to indicate an error
+		log.Printf("Error building request for URL: %s, Error: %v", l.URL, err)
+		l.Status = 0
+		return
+	}
+
+	resp, err := httpClient.Do(req)
+	if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+		resp.Body.Close()
+		req, err = http.NewRequest(http.MethodGet, l.URL, nil)
+		if err == nil {
+			resp, err = httpClient.Do(req)
+		}
+	}
+	if err != nil {
+		var connErr *client.ConnError
+		if errors.As(err, &connErr) {
+			log.Printf("Connection error fetching URL: %s, Error: %v", l.URL, err)
+			l.ConnErr = true
+		} else {
+			log.Printf("Error fetching URL: %s, Error: %v", l.URL, err)
+		}
+		l.Status = 0
 		return
 	}
 	defer resp.Body.Close()
 	l.Status = resp.StatusCode
 }
+
+// fetchAndArchive GETs a related resource in full and mirrors the raw
+// request/response bytes into warcWriter, recording the resulting status
+// on l the same way fetchStatus does. Unlike fetchStatus, it always uses
+// GET rather than HEAD, since archiving needs the response body.
+func (l *Link) fetchAndArchive(httpClient *client.Client, warcWriter *archive.Writer) {
+	req, err := http.NewRequest(http.MethodGet, l.URL, nil)
+	if err != nil {
+		log.Printf("Error building request for URL: %s, Error: %v", l.URL, err)
+		l.Status = 0
+		return
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var connErr *client.ConnError
+		if errors.As(err, &connErr) {
+			log.Printf("Connection error fetching URL: %s, Error: %v", l.URL, err)
+			l.ConnErr = true
+		} else {
+			log.Printf("Error fetching URL: %s, Error: %v", l.URL, err)
+		}
+		l.Status = 0
+		return
+	}
+	defer resp.Body.Close()
+
+	rawResp, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		log.Printf("Error capturing raw response for %s: %v", l.URL, err)
+	} else {
+		if rawReq, err := httputil.DumpRequestOut(req, false); err == nil {
+			if err := warcWriter.WriteRequest(l.URL, rawReq); err != nil {
+				log.Printf("Error writing WARC request record for %s: %v", l.URL, err)
+			}
+		}
+		if err := warcWriter.WriteResponse(l.URL, rawResp); err != nil {
+			log.Printf("Error writing WARC response record for %s: %v", l.URL, err)
+		}
+	}
+
+	l.Status = resp.StatusCode
+}
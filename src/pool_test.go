@@ -0,0 +1,122 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jshiles/broken_link_detector/archive"
+	"github.com/jshiles/broken_link_detector/client"
+)
+
+func TestFetchURLsConcurrentlyRespectsConcurrencyLimit(t *testing.T) {
+	const concurrency = 3
+
+	var inFlight, maxInFlight int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cur := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			prev := atomic.LoadInt64(&maxInFlight)
+			if cur <= prev || atomic.CompareAndSwapInt64(&maxInFlight, prev, cur) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	links := make([]Link, 10)
+	for i := range links {
+		links[i] = Link{URL: server.URL, Class: TagRelated}
+	}
+
+	httpClient := client.New(0, 0, "test-agent/1.0", 0)
+	fetchURLsConcurrently(links, concurrency, false, nil, httpClient)
+
+	if got := atomic.LoadInt64(&maxInFlight); got > concurrency {
+		t.Errorf("observed %d concurrent requests, want at most %d", got, concurrency)
+	}
+}
+
+func TestFetchStatusFallsBackToGetOn405(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	link := Link{URL: server.URL}
+	httpClient := client.New(0, 0, "test-agent/1.0", 0)
+	link.fetchStatus(httpClient, nil)
+
+	if link.Status != http.StatusOK {
+		t.Errorf("Status = %d, want %d after falling back to GET", link.Status, http.StatusOK)
+	}
+}
+
+func TestFetchStatusArchivesRelatedLinksWhenWarcWriterSet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("method = %s, want GET so the body can be archived", r.Method)
+		}
+		w.Write([]byte("body bytes"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "crawl.warc.gz")
+	warcWriter, err := archive.NewWriter(path, "test-agent/1.0")
+	if err != nil {
+		t.Fatalf("archive.NewWriter: %v", err)
+	}
+	defer warcWriter.Close()
+
+	link := Link{URL: server.URL, Class: TagRelated}
+	httpClient := client.New(0, 0, "test-agent/1.0", 0)
+	link.fetchStatus(httpClient, warcWriter)
+
+	if link.Status != http.StatusOK {
+		t.Errorf("Status = %d, want %d", link.Status, http.StatusOK)
+	}
+	if err := warcWriter.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	raw := readAllGzipMembersInPoolTest(t, path)
+	if !strings.Contains(raw, "WARC-Type: response") || !strings.Contains(raw, "body bytes") {
+		t.Errorf("expected the related resource's response body to be archived, got:\n%s", raw)
+	}
+}
+
+// readAllGzipMembersInPoolTest decompresses every concatenated gzip member
+// in the WARC file at path and returns their combined contents.
+func readAllGzipMembersInPoolTest(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	gr.Multistream(true)
+	data, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("reading gzip members: %v", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,186 @@
+package queue
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	pendingBucket    = []byte("pending")
+	doneBucket       = []byte("done")
+	brokenBucket     = []byte("broken")
+	connErrorsBucket = []byte("connErrors")
+	referrerBucket   = []byte("referrer")
+)
+
+// allBuckets lists every bucket the queue maintains, for creation and
+// for the fresh-start reset performed when NewBolt is called without
+// -resume.
+var allBuckets = [][]byte{pendingBucket, doneBucket, brokenBucket, connErrorsBucket, referrerBucket}
+
+// boltQueue persists the crawl frontier to a BoltDB file so an
+// interrupted crawl can be resumed with -resume.
+type boltQueue struct {
+	db *bolt.DB
+}
+
+// NewBolt opens (creating if necessary) a BoltDB-backed Queue at path. If
+// resume is false, any pending/done/broken state left over from a
+// previous crawl is discarded so this crawl starts fresh.
+func NewBolt(path string, resume bool) (Queue, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if !resume {
+			for _, b := range allBuckets {
+				if err := tx.DeleteBucket(b); err != nil && err != bolt.ErrBucketNotFound {
+					return err
+				}
+			}
+		}
+		for _, b := range allBuckets {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &boltQueue{db: db}, nil
+}
+
+// Push implements Queue.
+func (q *boltQueue) Push(url string, depth int, referrer string) (bool, error) {
+	added := false
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		pending := tx.Bucket(pendingBucket)
+		done := tx.Bucket(doneBucket)
+		if pending.Get([]byte(url)) != nil || done.Get([]byte(url)) != nil {
+			return nil
+		}
+		added = true
+		if referrer != "" {
+			if err := tx.Bucket(referrerBucket).Put([]byte(url), []byte(referrer)); err != nil {
+				return err
+			}
+		}
+		return pending.Put([]byte(url), encodeInt(depth))
+	})
+	return added, err
+}
+
+// Pop implements Queue. Items come back in byte order of their URL keys,
+// not in the order they were pushed.
+func (q *boltQueue) Pop() (Item, bool, error) {
+	var item Item
+	ok := false
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		pending := tx.Bucket(pendingBucket)
+		k, v := pending.Cursor().First()
+		if k == nil {
+			return nil
+		}
+		item = Item{URL: string(k), Depth: decodeInt(v)}
+		if ref := tx.Bucket(referrerBucket).Get(k); ref != nil {
+			item.Referrer = string(ref)
+		}
+		ok = true
+		return pending.Delete(k)
+	})
+	return item, ok, err
+}
+
+// MarkDone implements Queue.
+func (q *boltQueue) MarkDone(url string, status int, connErr bool) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		done := tx.Bucket(doneBucket)
+		if err := done.Put([]byte(url), encodeInt(status)); err != nil {
+			return err
+		}
+		if connErr {
+			return tx.Bucket(connErrorsBucket).Put([]byte(url), []byte{1})
+		}
+		if status >= 400 {
+			return tx.Bucket(brokenBucket).Put([]byte(url), encodeInt(status))
+		}
+		return nil
+	})
+}
+
+// Pending implements Queue.
+func (q *boltQueue) Pending() ([]Item, error) {
+	var items []Item
+	err := q.db.View(func(tx *bolt.Tx) error {
+		referrer := tx.Bucket(referrerBucket)
+		return tx.Bucket(pendingBucket).ForEach(func(k, v []byte) error {
+			item := Item{URL: string(k), Depth: decodeInt(v)}
+			if ref := referrer.Get(k); ref != nil {
+				item.Referrer = string(ref)
+			}
+			items = append(items, item)
+			return nil
+		})
+	})
+	return items, err
+}
+
+// Broken implements Queue.
+func (q *boltQueue) Broken() (map[string]int, error) {
+	out := make(map[string]int)
+	err := q.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(brokenBucket).ForEach(func(k, v []byte) error {
+			out[string(k)] = decodeInt(v)
+			return nil
+		})
+	})
+	return out, err
+}
+
+// ConnErrors implements Queue.
+func (q *boltQueue) ConnErrors() ([]string, error) {
+	var urls []string
+	err := q.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(connErrorsBucket).ForEach(func(k, v []byte) error {
+			urls = append(urls, string(k))
+			return nil
+		})
+	})
+	return urls, err
+}
+
+// Referrer implements Queue.
+func (q *boltQueue) Referrer(url string) (string, bool, error) {
+	var referrer string
+	var ok bool
+	err := q.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(referrerBucket).Get([]byte(url)); v != nil {
+			referrer, ok = string(v), true
+		}
+		return nil
+	})
+	return referrer, ok, err
+}
+
+// Close implements Queue.
+func (q *boltQueue) Close() error {
+	return q.db.Close()
+}
+
+func encodeInt(n int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(int64(n)))
+	return b
+}
+
+func decodeInt(b []byte) int {
+	return int(int64(binary.BigEndian.Uint64(b)))
+}
@@ -0,0 +1,100 @@
+package queue
+
+import "sync"
+
+// memoryQueue is the default, non-persistent Queue backed by in-process
+// maps. Its state is lost on restart, so a crawl using it cannot be
+// resumed.
+type memoryQueue struct {
+	mu         sync.Mutex
+	pending    []Item
+	seen       map[string]bool
+	referrer   map[string]string
+	broken     map[string]int
+	connErrors map[string]bool
+}
+
+// NewMemory returns a Queue that only exists for the lifetime of the
+// process.
+func NewMemory() Queue {
+	return &memoryQueue{
+		seen:       make(map[string]bool),
+		referrer:   make(map[string]string),
+		broken:     make(map[string]int),
+		connErrors: make(map[string]bool),
+	}
+}
+
+func (m *memoryQueue) Push(url string, depth int, referrer string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.seen[url] {
+		return false, nil
+	}
+	m.seen[url] = true
+	if referrer != "" {
+		m.referrer[url] = referrer
+	}
+	m.pending = append(m.pending, Item{URL: url, Depth: depth, Referrer: referrer})
+	return true, nil
+}
+
+func (m *memoryQueue) Pop() (Item, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.pending) == 0 {
+		return Item{}, false, nil
+	}
+	item := m.pending[0]
+	m.pending = m.pending[1:]
+	return item, true, nil
+}
+
+func (m *memoryQueue) MarkDone(url string, status int, connErr bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seen[url] = true
+	if connErr {
+		m.connErrors[url] = true
+	} else if status >= 400 {
+		m.broken[url] = status
+	}
+	return nil
+}
+
+func (m *memoryQueue) Pending() ([]Item, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Item, len(m.pending))
+	copy(out, m.pending)
+	return out, nil
+}
+
+func (m *memoryQueue) Broken() (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]int, len(m.broken))
+	for k, v := range m.broken {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (m *memoryQueue) ConnErrors() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]string, 0, len(m.connErrors))
+	for k := range m.connErrors {
+		out = append(out, k)
+	}
+	return out, nil
+}
+
+func (m *memoryQueue) Referrer(url string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ref, ok := m.referrer[url]
+	return ref, ok, nil
+}
+
+func (m *memoryQueue) Close() error { return nil }
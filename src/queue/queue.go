@@ -0,0 +1,48 @@
+// Package queue implements the crawl frontier: the set of URLs still to
+// visit, and the record of which URLs are done and which turned out to be
+// broken. A persistent implementation lets a crawl be resumed after an
+// interruption.
+package queue
+
+// Item is a unit of crawl work: a URL, the depth at which it was
+// discovered, and the URL of the page that linked to it (empty for the
+// seed).
+type Item struct {
+	URL      string
+	Depth    int
+	Referrer string
+}
+
+// Queue is the frontier of a crawl. Implementations must be safe for
+// concurrent use by multiple worker goroutines.
+type Queue interface {
+	// Push enqueues url at depth, discovered via referrer, unless it has
+	// already been pushed or marked done. added reports whether it was
+	// actually enqueued.
+	Push(url string, depth int, referrer string) (added bool, err error)
+	// Pop removes and returns the next pending item. ok is false when
+	// the frontier is currently empty.
+	Pop() (item Item, ok bool, err error)
+	// MarkDone records the outcome for url: status is the final HTTP
+	// status (meaningless when connErr is true), and connErr marks a
+	// transport-level failure (DNS, timeout, connection refused) rather
+	// than a genuine HTTP error status. A >=400 status or a connErr both
+	// record url as broken, but in their own buckets so the final report
+	// can tell a transient network blip apart from a real broken link.
+	MarkDone(url string, status int, connErr bool) error
+	// Pending returns every item still waiting to be processed, used to
+	// reseed a resumed crawl.
+	Pending() ([]Item, error)
+	// Broken returns every URL recorded with a genuine broken HTTP
+	// status, mapped to that status.
+	Broken() (map[string]int, error)
+	// ConnErrors returns every URL whose final attempt failed with a
+	// connection error rather than an HTTP status.
+	ConnErrors() ([]string, error)
+	// Referrer returns the referrer recorded for url when it was pushed.
+	// ok is false if url is unknown or was pushed with no referrer (i.e.
+	// it's the seed).
+	Referrer(url string) (referrer string, ok bool, err error)
+	// Close releases any resources held by the queue.
+	Close() error
+}
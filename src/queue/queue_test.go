@@ -0,0 +1,199 @@
+package queue
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// runQueueTests exercises the Queue contract against a freshly constructed
+// implementation, so both the in-memory and BoltDB backends can share the
+// same assertions.
+func runQueueTests(t *testing.T, newQueue func(t *testing.T) Queue) {
+	t.Run("PushDedupes", func(t *testing.T) {
+		q := newQueue(t)
+		defer q.Close()
+
+		added, err := q.Push("https://example.com/", 0, "")
+		if err != nil || !added {
+			t.Fatalf("first Push: added=%v err=%v", added, err)
+		}
+		added, err = q.Push("https://example.com/", 0, "")
+		if err != nil || added {
+			t.Fatalf("duplicate Push: added=%v err=%v, want false", added, err)
+		}
+	})
+
+	t.Run("PushAfterMarkDoneDoesNotReenqueue", func(t *testing.T) {
+		q := newQueue(t)
+		defer q.Close()
+
+		if _, err := q.Push("https://example.com/", 0, ""); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+		if _, _, err := q.Pop(); err != nil {
+			t.Fatalf("Pop: %v", err)
+		}
+		if err := q.MarkDone("https://example.com/", 200, false); err != nil {
+			t.Fatalf("MarkDone: %v", err)
+		}
+		added, err := q.Push("https://example.com/", 0, "")
+		if err != nil || added {
+			t.Fatalf("Push after MarkDone: added=%v err=%v, want false", added, err)
+		}
+	})
+
+	t.Run("PopReturnsPushedItemsAndEmptiesCleanly", func(t *testing.T) {
+		q := newQueue(t)
+		defer q.Close()
+
+		if _, err := q.Push("https://example.com/a", 1, ""); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+		if _, err := q.Push("https://example.com/b", 2, ""); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+
+		item, ok, err := q.Pop()
+		if err != nil || !ok || item.URL != "https://example.com/a" || item.Depth != 1 {
+			t.Fatalf("first Pop = %+v, ok=%v, err=%v", item, ok, err)
+		}
+		item, ok, err = q.Pop()
+		if err != nil || !ok || item.URL != "https://example.com/b" || item.Depth != 2 {
+			t.Fatalf("second Pop = %+v, ok=%v, err=%v", item, ok, err)
+		}
+		_, ok, err = q.Pop()
+		if err != nil || ok {
+			t.Fatalf("Pop on empty queue: ok=%v, err=%v, want false", ok, err)
+		}
+	})
+
+	t.Run("MarkDoneRecordsBrokenAndConnErrorsSeparately", func(t *testing.T) {
+		q := newQueue(t)
+		defer q.Close()
+
+		if err := q.MarkDone("https://example.com/broken", 404, false); err != nil {
+			t.Fatalf("MarkDone: %v", err)
+		}
+		if err := q.MarkDone("https://example.com/down", 0, true); err != nil {
+			t.Fatalf("MarkDone: %v", err)
+		}
+		if err := q.MarkDone("https://example.com/ok", 200, false); err != nil {
+			t.Fatalf("MarkDone: %v", err)
+		}
+
+		broken, err := q.Broken()
+		if err != nil {
+			t.Fatalf("Broken: %v", err)
+		}
+		if status, ok := broken["https://example.com/broken"]; !ok || status != 404 {
+			t.Errorf("Broken() = %v, want 404 for the broken URL", broken)
+		}
+		if _, ok := broken["https://example.com/down"]; ok {
+			t.Errorf("Broken() should not include a connection-error URL: %v", broken)
+		}
+
+		connErrors, err := q.ConnErrors()
+		if err != nil {
+			t.Fatalf("ConnErrors: %v", err)
+		}
+		if len(connErrors) != 1 || connErrors[0] != "https://example.com/down" {
+			t.Errorf("ConnErrors() = %v, want [https://example.com/down]", connErrors)
+		}
+	})
+
+	t.Run("ReferrerIsRecordedOnPush", func(t *testing.T) {
+		q := newQueue(t)
+		defer q.Close()
+
+		if _, err := q.Push("https://example.com/", 0, ""); err != nil {
+			t.Fatalf("Push seed: %v", err)
+		}
+		if _, err := q.Push("https://example.com/child", 1, "https://example.com/"); err != nil {
+			t.Fatalf("Push child: %v", err)
+		}
+
+		ref, ok, err := q.Referrer("https://example.com/child")
+		if err != nil || !ok || ref != "https://example.com/" {
+			t.Errorf("Referrer(child) = %q, ok=%v, err=%v, want https://example.com/", ref, ok, err)
+		}
+		_, ok, err = q.Referrer("https://example.com/")
+		if err != nil || ok {
+			t.Errorf("Referrer(seed) ok=%v, err=%v, want ok=false (no referrer)", ok, err)
+		}
+	})
+}
+
+func TestMemoryQueue(t *testing.T) {
+	runQueueTests(t, func(t *testing.T) Queue {
+		return NewMemory()
+	})
+}
+
+func TestBoltQueue(t *testing.T) {
+	runQueueTests(t, func(t *testing.T) Queue {
+		path := filepath.Join(t.TempDir(), "state.db")
+		q, err := NewBolt(path, false)
+		if err != nil {
+			t.Fatalf("NewBolt: %v", err)
+		}
+		return q
+	})
+}
+
+func TestNewBoltResumeKeepsPriorState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+
+	q, err := NewBolt(path, false)
+	if err != nil {
+		t.Fatalf("NewBolt: %v", err)
+	}
+	if _, err := q.Push("https://example.com/pending", 0, ""); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	q, err = NewBolt(path, true)
+	if err != nil {
+		t.Fatalf("NewBolt (resume): %v", err)
+	}
+	defer q.Close()
+
+	pending, err := q.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].URL != "https://example.com/pending" {
+		t.Errorf("Pending() = %+v, want the previously pushed URL", pending)
+	}
+}
+
+func TestNewBoltWithoutResumeStartsFresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+
+	q, err := NewBolt(path, false)
+	if err != nil {
+		t.Fatalf("NewBolt: %v", err)
+	}
+	if _, err := q.Push("https://example.com/pending", 0, ""); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	q, err = NewBolt(path, false)
+	if err != nil {
+		t.Fatalf("NewBolt (fresh): %v", err)
+	}
+	defer q.Close()
+
+	pending, err := q.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Pending() = %+v, want none after a fresh (non-resume) start", pending)
+	}
+}
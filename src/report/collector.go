@@ -0,0 +1,36 @@
+package report
+
+import "sync"
+
+// Collector accumulates PageResult entries from concurrent crawl workers
+// so they can be written out once the crawl finishes.
+type Collector struct {
+	mu      sync.Mutex
+	results []PageResult
+}
+
+// NewCollector returns an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Add records the broken links found while checking page's links. It's a
+// no-op for an empty slice, so pages with nothing broken don't clutter
+// the report.
+func (c *Collector) Add(page string, broken []BrokenLink) {
+	if len(broken) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, PageResult{Page: page, Broken: broken})
+}
+
+// Results returns a snapshot of every PageResult recorded so far.
+func (c *Collector) Results() []PageResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]PageResult, len(c.results))
+	copy(out, c.results)
+	return out
+}
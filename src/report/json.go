@@ -0,0 +1,14 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteJSON writes results as a JSON document mapping each crawled page to
+// its list of broken outbound links.
+func WriteJSON(w io.Writer, results []PageResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
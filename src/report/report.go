@@ -0,0 +1,46 @@
+// Package report renders crawl results as structured data, so a crawl's
+// broken links can be inspected by tooling instead of only scraped from
+// stdout logs.
+package report
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// BrokenLink describes one broken outbound link found while checking a
+// page's links.
+type BrokenLink struct {
+	URL       string `json:"url"`
+	Status    int    `json:"status"`
+	ConnError bool   `json:"connError"`
+	// Referrer is the path from the seed URL down to the page the link
+	// was found on, e.g. [seed, ..., page].
+	Referrer  []string  `json:"referrerChain"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PageResult is the set of broken links found on a single crawled page.
+type PageResult struct {
+	Page   string       `json:"page"`
+	Broken []BrokenLink `json:"brokenLinks"`
+}
+
+// Write renders results in the given format ("json" or "sarif") to path.
+func Write(path, format string, results []PageResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer f.Close()
+
+	switch format {
+	case "json":
+		return WriteJSON(f, results)
+	case "sarif":
+		return WriteSARIF(f, results)
+	default:
+		return fmt.Errorf("unsupported report format %q (want json or sarif)", format)
+	}
+}
@@ -0,0 +1,86 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleResults() []PageResult {
+	return []PageResult{{
+		Page: "https://example.com/",
+		Broken: []BrokenLink{{
+			URL:       "https://example.com/dead",
+			Status:    404,
+			ConnError: false,
+			Referrer:  []string{"https://example.com/", "https://example.com/"},
+			Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		}},
+	}}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, sampleResults()); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+
+	var decoded []PageResult
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("decoding output: %v\n%s", err, buf.String())
+	}
+	if len(decoded) != 1 || len(decoded[0].Broken) != 1 {
+		t.Fatalf("decoded = %+v, want one page with one broken link", decoded)
+	}
+	if decoded[0].Broken[0].Status != 404 {
+		t.Errorf("Status = %d, want 404", decoded[0].Broken[0].Status)
+	}
+	if !strings.Contains(buf.String(), `"referrerChain"`) {
+		t.Errorf("expected a referrerChain field in the output, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, sampleResults()); err != nil {
+		t.Fatalf("WriteSARIF: %v", err)
+	}
+
+	var doc sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("decoding output: %v\n%s", err, buf.String())
+	}
+	if doc.Version != "2.1.0" {
+		t.Errorf("Version = %q, want 2.1.0", doc.Version)
+	}
+	if len(doc.Runs) != 1 || len(doc.Runs[0].Results) != 1 {
+		t.Fatalf("doc = %+v, want one run with one result", doc)
+	}
+	result := doc.Runs[0].Results[0]
+	if result.RuleID != brokenLinkRuleID {
+		t.Errorf("RuleID = %q, want %q", result.RuleID, brokenLinkRuleID)
+	}
+	if result.Locations[0].PhysicalLocation.ArtifactLocation.URI != "https://example.com/" {
+		t.Errorf("artifact URI = %q, want the page URL", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	}
+}
+
+func TestWriteRejectsUnknownFormat(t *testing.T) {
+	err := Write(t.TempDir()+"/report.out", "yaml", sampleResults())
+	if err == nil {
+		t.Fatal("Write with an unknown format should return an error")
+	}
+}
+
+func TestCollectorSkipsPagesWithNothingBroken(t *testing.T) {
+	c := NewCollector()
+	c.Add("https://example.com/clean", nil)
+	c.Add("https://example.com/dirty", []BrokenLink{{URL: "https://example.com/dead", Status: 404}})
+
+	results := c.Results()
+	if len(results) != 1 || results[0].Page != "https://example.com/dirty" {
+		t.Errorf("Results() = %+v, want only the page with broken links", results)
+	}
+}
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// brokenLinkRuleID identifies the single SARIF rule this package reports
+// against: an outbound link that turned out to be broken.
+const brokenLinkRuleID = "broken-link"
+
+// The sarif* types below are a minimal SARIF 2.1.0 document, just enough
+// to surface broken links as results a GitHub code-scanning run can
+// annotate on a PR diff.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// WriteSARIF writes results as a SARIF 2.1.0 log, one result per broken
+// link, so the crawl can be wired into a CI code-scanning pipeline.
+func WriteSARIF(w io.Writer, results []PageResult) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:  "broken_link_detector",
+				Rules: []sarifRule{{ID: brokenLinkRuleID, Name: "BrokenLink"}},
+			},
+		},
+	}
+
+	for _, page := range results {
+		for _, link := range page.Broken {
+			msg := fmt.Sprintf("Broken link %s (status %d) found on %s, reached via %s", link.URL, link.Status, page.Page, strings.Join(link.Referrer, " -> "))
+			if link.ConnError {
+				msg = fmt.Sprintf("Connection error fetching %s, found on %s, reached via %s", link.URL, page.Page, strings.Join(link.Referrer, " -> "))
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:  brokenLinkRuleID,
+				Level:   "error",
+				Message: sarifMessage{Text: msg},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: page.Page},
+					},
+				}},
+			})
+		}
+	}
+
+	doc := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,230 @@
+// Package robots fetches and caches each host's robots.txt so the crawler
+// can skip URLs a site has disallowed for its user agent, and can honor a
+// site's requested Crawl-delay.
+package robots
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jshiles/broken_link_detector/client"
+)
+
+// Checker consults robots.txt rules on behalf of a single user agent,
+// fetching and caching each host's rules the first time that host is seen.
+type Checker struct {
+	httpClient *client.Client
+	userAgent  string
+
+	mu    sync.Mutex
+	rules map[string]*hostRules
+}
+
+// New returns a Checker that fetches robots.txt via httpClient and
+// evaluates it for userAgent. A site's Crawl-delay directive, when
+// present, is applied to httpClient's per-host rate limit as soon as that
+// site's rules are loaded.
+func New(httpClient *client.Client, userAgent string) *Checker {
+	return &Checker{
+		httpClient: httpClient,
+		userAgent:  userAgent,
+		rules:      make(map[string]*hostRules),
+	}
+}
+
+// Allowed reports whether rawURL may be fetched under its host's
+// robots.txt rules. A host whose robots.txt can't be retrieved (missing,
+// blocked, etc.) is treated as allowing everything, the conventional
+// behavior for crawlers; the error is still returned so callers can log it.
+func (c *Checker) Allowed(rawURL string) (bool, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false, err
+	}
+	rules, err := c.rulesFor(parsed)
+	return rules.allowed(parsed.Path), err
+}
+
+// rulesFor returns the cached hostRules for u's host, fetching and parsing
+// robots.txt the first time the host is seen.
+func (c *Checker) rulesFor(u *url.URL) (*hostRules, error) {
+	host := u.Host
+
+	c.mu.Lock()
+	rules, ok := c.rules[host]
+	c.mu.Unlock()
+	if ok {
+		return rules, nil
+	}
+
+	rules, err := c.fetch(u)
+	if err != nil {
+		rules = &hostRules{}
+	} else if rules.hasCrawlDelay {
+		c.httpClient.EnsureMinInterval(host, rules.crawlDelay)
+	}
+
+	c.mu.Lock()
+	c.rules[host] = rules
+	c.mu.Unlock()
+	return rules, err
+}
+
+// fetch retrieves and parses the robots.txt for u's host.
+func (c *Checker) fetch(u *url.URL) (*hostRules, error) {
+	robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	req, err := http.NewRequest(http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("robots.txt for %s returned status %d", u.Host, resp.StatusCode)
+	}
+	return parse(resp.Body, c.userAgent), nil
+}
+
+// hostRules is the set of robots.txt directives that apply to one
+// Checker's user agent on a single host.
+type hostRules struct {
+	disallow      []string
+	allow         []string
+	crawlDelay    time.Duration
+	hasCrawlDelay bool
+}
+
+// allowed applies the longest-matching-rule-wins convention: the
+// Allow/Disallow rule with the longest matching path prefix governs, with
+// Allow winning ties.
+func (r *hostRules) allowed(path string) bool {
+	bestLen := -1
+	bestAllow := true
+	for _, rule := range r.disallow {
+		if rule != "" && strings.HasPrefix(path, rule) && len(rule) > bestLen {
+			bestLen, bestAllow = len(rule), false
+		}
+	}
+	for _, rule := range r.allow {
+		if rule != "" && strings.HasPrefix(path, rule) && len(rule) >= bestLen {
+			bestLen, bestAllow = len(rule), true
+		}
+	}
+	return bestAllow
+}
+
+// group is one User-agent: block of a robots.txt document, before it's
+// been matched against a specific user agent.
+type group struct {
+	agents        []string
+	disallow      []string
+	allow         []string
+	crawlDelay    time.Duration
+	hasCrawlDelay bool
+}
+
+// parse reads a robots.txt document and returns the rules that apply to
+// userAgent, preferring a group addressed to it by name over the
+// wildcard "*" group.
+func parse(r io.Reader, userAgent string) *hostRules {
+	var groups []*group
+	var current *group
+	startingNewGroup := true
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		field, value, ok := splitDirective(line)
+		if !ok {
+			continue
+		}
+
+		switch field {
+		case "user-agent":
+			if current == nil || !startingNewGroup {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, value)
+			startingNewGroup = true
+			continue
+		case "disallow":
+			if current != nil {
+				current.disallow = append(current.disallow, value)
+			}
+		case "allow":
+			if current != nil {
+				current.allow = append(current.allow, value)
+			}
+		case "crawl-delay":
+			if current != nil {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil {
+					current.crawlDelay = time.Duration(secs * float64(time.Second))
+					current.hasCrawlDelay = true
+				}
+			}
+		}
+		startingNewGroup = false
+	}
+
+	group := matchGroup(groups, userAgent)
+	if group == nil {
+		return &hostRules{}
+	}
+	return &hostRules{
+		disallow:      group.disallow,
+		allow:         group.allow,
+		crawlDelay:    group.crawlDelay,
+		hasCrawlDelay: group.hasCrawlDelay,
+	}
+}
+
+// splitDirective parses a "field: value" robots.txt line.
+func splitDirective(line string) (field, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", "", false
+	}
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]), true
+}
+
+// matchGroup picks the group addressed to userAgent's product token,
+// falling back to the wildcard "*" group when no group names it directly.
+func matchGroup(groups []*group, userAgent string) *group {
+	token := strings.ToLower(strings.SplitN(userAgent, "/", 2)[0])
+
+	var wildcard *group
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			agent = strings.ToLower(strings.TrimSpace(agent))
+			switch {
+			case agent == "*":
+				if wildcard == nil {
+					wildcard = g
+				}
+			case strings.Contains(token, agent) || strings.Contains(agent, token):
+				return g
+			}
+		}
+	}
+	return wildcard
+}
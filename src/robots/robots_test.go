@@ -0,0 +1,110 @@
+package robots
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jshiles/broken_link_detector/client"
+)
+
+func TestParseMatchesSpecificAgentOverWildcard(t *testing.T) {
+	const doc = `
+User-agent: *
+Disallow: /private/
+
+User-agent: mybot
+Disallow: /bot-only/
+Crawl-delay: 2
+`
+	rules := parse(strings.NewReader(doc), "mybot/1.0")
+	if rules.allowed("/bot-only/page") {
+		t.Error("expected /bot-only/ to be disallowed for mybot, which has its own group")
+	}
+	if !rules.allowed("/private/page") {
+		t.Error("expected /private/ (only in the wildcard group) to be allowed once a specific group matches")
+	}
+	if !rules.hasCrawlDelay || rules.crawlDelay != 2*time.Second {
+		t.Errorf("crawlDelay = %v, hasCrawlDelay = %v, want 2s, true", rules.crawlDelay, rules.hasCrawlDelay)
+	}
+}
+
+func TestParseFallsBackToWildcard(t *testing.T) {
+	const doc = `
+User-agent: *
+Disallow: /private/
+`
+	rules := parse(strings.NewReader(doc), "mybot/1.0")
+	if rules.allowed("/private/page") {
+		t.Error("expected /private/ to be disallowed under the wildcard group")
+	}
+	if rules.hasCrawlDelay {
+		t.Error("expected no Crawl-delay to be recorded")
+	}
+}
+
+func TestHostRulesAllowedLongestRuleWins(t *testing.T) {
+	rules := &hostRules{
+		disallow: []string{"/docs/"},
+		allow:    []string{"/docs/public/"},
+	}
+	if rules.allowed("/docs/private/page") == true {
+		t.Error("/docs/private/ should be disallowed (only the shorter /docs/ rule matches)")
+	}
+	if !rules.allowed("/docs/public/page") {
+		t.Error("/docs/public/ should be allowed: its Allow rule is longer (more specific) than Disallow /docs/")
+	}
+	if !rules.allowed("/blog/post") {
+		t.Error("/blog/post matches no rule, so it should be allowed")
+	}
+}
+
+func TestCheckerAllowedFetchesAndCaches(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+	}))
+	defer server.Close()
+
+	c := New(client.New(0, 0, "test-agent/1.0", 0), "test-agent/1.0")
+
+	for i := 0; i < 3; i++ {
+		allowed, err := c.Allowed(server.URL + "/private/page")
+		if err != nil {
+			t.Fatalf("Allowed: %v", err)
+		}
+		if allowed {
+			t.Error("expected /private/page to be disallowed")
+		}
+	}
+	if hits != 1 {
+		t.Errorf("robots.txt was fetched %d times, want 1 (cached after the first)", hits)
+	}
+
+	allowed, err := c.Allowed(server.URL + "/public/page")
+	if err != nil {
+		t.Fatalf("Allowed: %v", err)
+	}
+	if !allowed {
+		t.Error("expected /public/page to be allowed")
+	}
+}
+
+func TestCheckerAllowedAllowsEverythingWhenRobotsMissing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	c := New(client.New(0, 0, "test-agent/1.0", 0), "test-agent/1.0")
+	allowed, err := c.Allowed(server.URL + "/anything")
+	if err == nil {
+		t.Error("expected an error reporting the missing robots.txt")
+	}
+	if !allowed {
+		t.Error("a host with no retrievable robots.txt should allow everything")
+	}
+}
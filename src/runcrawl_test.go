@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jshiles/broken_link_detector/client"
+	"github.com/jshiles/broken_link_detector/queue"
+)
+
+// TestRunCrawlResumeBeyondQueueCapacity guards against a panic ("send on
+// closed channel") that occurs if the resume prefill in runCrawl races the
+// worker pool it started: incrementing inFlight one item at a time while
+// workers are already draining avail can let inFlight transiently hit
+// zero and close avail before every pending item has been sent.
+func TestRunCrawlResumeBeyondQueueCapacity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>no links here</body></html>"))
+	}))
+	defer server.Close()
+
+	q := queue.NewMemory()
+	defer q.Close()
+
+	const pendingCount = queueCapacity + 100
+	for i := 0; i < pendingCount; i++ {
+		if _, err := q.Push(fmt.Sprintf("%s/page/%d", server.URL, i), 0, ""); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+	}
+
+	seed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	httpClient := client.New(0, 0, "test-agent/1.0", 0)
+
+	runCrawl(server.URL, 0, 4, false, nil, DepthScope{}, seed, q, true, httpClient, nil, "", "")
+
+	pending, err := q.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Pending() = %d items left, want 0 once the resumed crawl finishes", len(pending))
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Scope decides whether a discovered link is eligible to be followed or
+// status-checked during a crawl. It is consulted both before recursing
+// into a link and before enqueuing it for a status check, so the same
+// policy governs both decisions.
+type Scope interface {
+	// Allowed reports whether linkURL, classified as class and found at
+	// depth (relative to maxDepth) while crawling seed, is in scope.
+	Allowed(seed *url.URL, linkURL string, class LinkClass, depth, maxDepth int) bool
+}
+
+// SeedHostScope restricts a crawl to links that share the seed URL's host.
+type SeedHostScope struct{}
+
+// Allowed implements Scope.
+func (SeedHostScope) Allowed(seed *url.URL, linkURL string, class LinkClass, depth, maxDepth int) bool {
+	parsed, err := url.Parse(linkURL)
+	if err != nil {
+		return false
+	}
+	return parsed.Host == seed.Host
+}
+
+// SeedPrefixScope restricts a crawl to links whose absolute URL has one of
+// the seed's URL as a prefix, e.g. to keep a crawl under /docs/.
+type SeedPrefixScope struct{}
+
+// Allowed implements Scope.
+func (SeedPrefixScope) Allowed(seed *url.URL, linkURL string, class LinkClass, depth, maxDepth int) bool {
+	return strings.HasPrefix(linkURL, seed.String())
+}
+
+// DepthScope allows primary links through only while they fall within
+// maxDepth, but always allows related links (images, stylesheets,
+// scripts, CSS url() references) through so they can still be checked
+// for broken status regardless of depth.
+type DepthScope struct{}
+
+// Allowed implements Scope.
+func (DepthScope) Allowed(seed *url.URL, linkURL string, class LinkClass, depth, maxDepth int) bool {
+	if class == TagRelated {
+		return true
+	}
+	return depth <= maxDepth
+}
+
+// newScope maps a -scope flag value to a Scope implementation.
+func newScope(name string) Scope {
+	switch name {
+	case "host":
+		return SeedHostScope{}
+	case "prefix":
+		return SeedPrefixScope{}
+	default:
+		return DepthScope{}
+	}
+}
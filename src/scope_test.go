@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %v", raw, err)
+	}
+	return u
+}
+
+func TestSeedHostScope(t *testing.T) {
+	seed := mustParseURL(t, "https://example.com/")
+	scope := SeedHostScope{}
+
+	cases := []struct {
+		link string
+		want bool
+	}{
+		{"https://example.com/about", true},
+		{"https://other.com/about", false},
+		{"not a url", false},
+	}
+	for _, c := range cases {
+		if got := scope.Allowed(seed, c.link, TagPrimary, 0, 1); got != c.want {
+			t.Errorf("Allowed(%q) = %v, want %v", c.link, got, c.want)
+		}
+	}
+}
+
+func TestSeedPrefixScope(t *testing.T) {
+	seed := mustParseURL(t, "https://example.com/docs/")
+	scope := SeedPrefixScope{}
+
+	cases := []struct {
+		link string
+		want bool
+	}{
+		{"https://example.com/docs/intro", true},
+		{"https://example.com/blog/post", false},
+	}
+	for _, c := range cases {
+		if got := scope.Allowed(seed, c.link, TagPrimary, 0, 1); got != c.want {
+			t.Errorf("Allowed(%q) = %v, want %v", c.link, got, c.want)
+		}
+	}
+}
+
+func TestDepthScope(t *testing.T) {
+	seed := mustParseURL(t, "https://example.com/")
+	scope := DepthScope{}
+
+	if !scope.Allowed(seed, "https://anywhere.example/img.png", TagRelated, 99, 1) {
+		t.Error("related links should be allowed regardless of depth")
+	}
+	if !scope.Allowed(seed, "https://example.com/a", TagPrimary, 1, 1) {
+		t.Error("primary link at maxDepth should be allowed")
+	}
+	if scope.Allowed(seed, "https://example.com/a", TagPrimary, 2, 1) {
+		t.Error("primary link beyond maxDepth should not be allowed")
+	}
+}
+
+func TestNewScope(t *testing.T) {
+	cases := []struct {
+		name string
+		want Scope
+	}{
+		{"host", SeedHostScope{}},
+		{"prefix", SeedPrefixScope{}},
+		{"depth", DepthScope{}},
+		{"unknown", DepthScope{}},
+	}
+	for _, c := range cases {
+		if got := newScope(c.name); got != c.want {
+			t.Errorf("newScope(%q) = %#v, want %#v", c.name, got, c.want)
+		}
+	}
+}